@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	chatbotv1 "github.com/openshift/ci-chat-bot/pkg/apis/chatbot/v1"
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+	"github.com/openshift/ci-chat-bot/pkg/prow/readiness"
+)
+
+// credentialsSecretName is the name of the Secret a ClusterRequest's
+// kubeconfig is stored under, relative to the target namespace.
+const credentialsSecretName = "cluster-credentials"
+
+// ensureClusterRequest creates the ClusterRequest that is the source of
+// truth for job's launch, or returns the existing one if launchJob is
+// resuming after a restart.
+func (m *jobManager) ensureClusterRequest(job *Job) (*chatbotv1.ClusterRequest, error) {
+	cr := &chatbotv1.ClusterRequest{
+		TypeMeta: metav1.TypeMeta{APIVersion: chatbotv1.SchemeGroupVersion.String(), Kind: "ClusterRequest"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name,
+			Namespace: m.prowNamespace,
+		},
+		Spec: chatbotv1.ClusterRequestSpec{
+			Requester:      job.RequestedBy,
+			Channel:        job.RequestedChannel,
+			Mode:           job.Mode,
+			JobName:        job.JobName,
+			InstallImage:   job.InstallImage,
+			InstallVersion: job.InstallVersion,
+			UpgradeImage:   job.UpgradeImage,
+			UpgradeVersion: job.UpgradeVersion,
+			TTL:            metav1.Duration{Duration: job.TTL},
+			LaunchTimeouts: clusterRequestLaunchTimeouts(job.LaunchTimeouts),
+			ReadyPolicy:    string(job.ReadyPolicy),
+		},
+		Status: chatbotv1.ClusterRequestStatus{
+			Phase: chatbotv1.ClusterRequestPhasePending,
+		},
+	}
+
+	_, err := m.clusterRequestClient.Namespace(m.prowNamespace).Create(prow.ObjectToUnstructured(cr), metav1.CreateOptions{})
+	if err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("could not create cluster request: %v", err)
+		}
+		uns, err := m.clusterRequestClient.Namespace(m.prowNamespace).Get(job.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve existing cluster request: %v", err)
+		}
+		if err := prow.UnstructuredToObject(uns, cr); err != nil {
+			return nil, fmt.Errorf("could not decode existing cluster request: %v", err)
+		}
+	}
+	return cr, nil
+}
+
+// updateClusterRequestStatus persists cr.Status, which is the bot's
+// authoritative record of how far a launch has progressed.
+func (m *jobManager) updateClusterRequestStatus(cr *chatbotv1.ClusterRequest) error {
+	_, err := m.clusterRequestClient.Namespace(m.prowNamespace).UpdateStatus(prow.ObjectToUnstructured(cr), metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update cluster request %s status: %v", cr.Name, err)
+	}
+	return nil
+}
+
+// setClusterRequestCondition records or updates a single condition, leaving
+// the others untouched, matching the upsert behavior of the standard
+// Kubernetes condition helpers.
+func setClusterRequestCondition(cr *chatbotv1.ClusterRequest, condType chatbotv1.ClusterRequestConditionType, status corev1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+	for i := range cr.Status.Conditions {
+		if cr.Status.Conditions[i].Type != condType {
+			continue
+		}
+		if cr.Status.Conditions[i].Status != status {
+			cr.Status.Conditions[i].LastTransitionTime = now
+		}
+		cr.Status.Conditions[i].Status = status
+		cr.Status.Conditions[i].Reason = reason
+		cr.Status.Conditions[i].Message = message
+		return
+	}
+	cr.Status.Conditions = append(cr.Status.Conditions, chatbotv1.ClusterRequestCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// persistCredentials stores kubeconfig in a Secret in namespace rather than
+// on the in-memory Job or the ClusterRequest itself, and returns a reference
+// to it for Status.CredentialsSecretRef.
+func (m *jobManager) persistCredentials(namespace, kubeconfig string) (*corev1.LocalObjectReference, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      credentialsSecretName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"kubeconfig": []byte(kubeconfig),
+		},
+	}
+	_, err := m.coreClient.Core().Secrets(namespace).Create(secret)
+	if err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("could not create credentials secret: %v", err)
+		}
+		patch := map[string]interface{}{"data": map[string][]byte{"kubeconfig": []byte(kubeconfig)}}
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := m.coreClient.Core().Secrets(namespace).Patch(credentialsSecretName, types.MergePatchType, raw); err != nil {
+			return nil, fmt.Errorf("could not update credentials secret: %v", err)
+		}
+	}
+	return &corev1.LocalObjectReference{Name: credentialsSecretName}, nil
+}
+
+// ReconcileClusterRequest implements clusterrequest.Launcher. It is called by
+// the controller on startup for every existing ClusterRequest and again on
+// every add/update, and resumes a launch that was interrupted mid-flight by
+// re-running launchJob against the request's recorded state.
+func (m *jobManager) ReconcileClusterRequest(cr *chatbotv1.ClusterRequest) error {
+	switch cr.Status.Phase {
+	case chatbotv1.ClusterRequestPhaseRunning, chatbotv1.ClusterRequestPhaseFailed, chatbotv1.ClusterRequestPhaseExpired:
+		// terminal: nothing left to do
+		return nil
+	}
+
+	job := &Job{
+		Name:             cr.Name,
+		Mode:             cr.Spec.Mode,
+		JobName:          cr.Spec.JobName,
+		RequestedBy:      cr.Spec.Requester,
+		RequestedChannel: cr.Spec.Channel,
+		InstallImage:     cr.Spec.InstallImage,
+		InstallVersion:   cr.Spec.InstallVersion,
+		UpgradeImage:     cr.Spec.UpgradeImage,
+		UpgradeVersion:   cr.Spec.UpgradeVersion,
+		TTL:              cr.Spec.TTL.Duration,
+		LaunchTimeouts:   launchTimeoutsFromClusterRequest(cr.Spec.LaunchTimeouts),
+		ReadyPolicy:      readiness.Policy(cr.Spec.ReadyPolicy),
+	}
+	return m.launchJob(job)
+}
+
+// clusterRequestLaunchTimeouts converts a job's LaunchTimeouts override into
+// its ClusterRequestSpec representation, so ReconcileClusterRequest can
+// rebuild the same override after a crash instead of falling back to the
+// manager's defaults. Returns nil if t is nil.
+func clusterRequestLaunchTimeouts(t *LaunchTimeouts) *chatbotv1.ClusterRequestLaunchTimeouts {
+	if t == nil {
+		return nil
+	}
+	return &chatbotv1.ClusterRequestLaunchTimeouts{
+		URLWait:                      metav1.Duration{Duration: t.URLWait},
+		URLPollInterval:              metav1.Duration{Duration: t.URLPollInterval},
+		ProwPodWait:                  metav1.Duration{Duration: t.ProwPodWait},
+		ProwPodPollInterval:          metav1.Duration{Duration: t.ProwPodPollInterval},
+		SetupWait:                    metav1.Duration{Duration: t.SetupWait},
+		SetupPollInterval:            metav1.Duration{Duration: t.SetupPollInterval},
+		KubeconfigWait:               metav1.Duration{Duration: t.KubeconfigWait},
+		KubeconfigPollInterval:       metav1.Duration{Duration: t.KubeconfigPollInterval},
+		ClusterReachableWait:         metav1.Duration{Duration: t.ClusterReachableWait},
+		ClusterReachablePollInterval: metav1.Duration{Duration: t.ClusterReachablePollInterval},
+	}
+}
+
+// launchTimeoutsFromClusterRequest is the inverse of clusterRequestLaunchTimeouts.
+// Returns nil if t is nil.
+func launchTimeoutsFromClusterRequest(t *chatbotv1.ClusterRequestLaunchTimeouts) *LaunchTimeouts {
+	if t == nil {
+		return nil
+	}
+	return &LaunchTimeouts{
+		URLWait:                      t.URLWait.Duration,
+		URLPollInterval:              t.URLPollInterval.Duration,
+		ProwPodWait:                  t.ProwPodWait.Duration,
+		ProwPodPollInterval:          t.ProwPodPollInterval.Duration,
+		SetupWait:                    t.SetupWait.Duration,
+		SetupPollInterval:            t.SetupPollInterval.Duration,
+		KubeconfigWait:               t.KubeconfigWait.Duration,
+		KubeconfigPollInterval:       t.KubeconfigPollInterval.Duration,
+		ClusterReachableWait:         t.ClusterReachableWait.Duration,
+		ClusterReachablePollInterval: t.ClusterReachablePollInterval.Duration,
+	}
+}
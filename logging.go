@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2/klogr"
+)
+
+// baseLogger is the bot's root logr.Logger, backed by klog/v2 so launch
+// pipeline logs share the same verbosity flags and output plumbing
+// (-v, -logtostderr, ...) as the rest of the binary.
+var baseLogger logr.Logger = klogr.New()
+
+// jobLogger returns a logger carrying the keyed fields needed to correlate
+// every log line produced by a single launch, even when several launches are
+// running concurrently: job_name, requested_by, mode, namespace, and
+// prow_job. requestID additionally ties the lines back to the Slack
+// interaction that triggered the launch.
+func jobLogger(job *Job, namespace, requestID string) logr.Logger {
+	return baseLogger.WithValues(
+		"job_name", job.Name,
+		"requested_by", job.RequestedBy,
+		"mode", job.Mode,
+		"namespace", namespace,
+		"prow_job", job.Name,
+		"request_id", requestID,
+	)
+}
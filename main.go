@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
+
+	chatbotv1 "github.com/openshift/ci-chat-bot/pkg/apis/chatbot/v1"
+	"github.com/openshift/ci-chat-bot/pkg/controller/clusterrequest"
+	"github.com/openshift/ci-chat-bot/pkg/scheduler"
+)
+
+// prowJobResource identifies the prow.k8s.io ProwJob custom resource that
+// launchJob creates and watches.
+var prowJobResource = schema.GroupVersionResource{Group: "prow.k8s.io", Version: "v1", Resource: "prowjobs"}
+
+// clusterRequestResource identifies the chatbot.openshift.io ClusterRequest
+// custom resource (see pkg/apis/chatbot/v1 and its CRD manifest under
+// manifests/).
+var clusterRequestResource = chatbotv1.SchemeGroupVersion.WithResource("clusterrequests")
+
+func main() {
+	var kubeconfig, prowNamespace string
+	pflag.StringVar(&kubeconfig, "kubeconfig", "", "Path to a kubeconfig file; defaults to in-cluster config.")
+	pflag.StringVar(&prowNamespace, "prow-namespace", "ci", "Namespace ProwJobs and ClusterRequests are created in.")
+
+	// registered here, before Parse, so operators can tune every launch
+	// phase's timeout and poll interval without a rebuild
+	launchTimeouts := DefaultLaunchTimeouts()
+	launchTimeouts.AddFlags(pflag.CommandLine)
+
+	pflag.Parse()
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not load cluster config: %v\n", err)
+		os.Exit(1)
+	}
+
+	m, err := NewJobManager(cfg, nil, prowNamespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: could not construct job manager: %v\n", err)
+		os.Exit(1)
+	}
+	m.launchTimeouts = launchTimeouts
+
+	run(m)
+}
+
+// run starts m's background work and blocks forever driving it. Launches
+// interrupted by a crash are resumed here: the clusterrequest controller's
+// initial List populates every existing ClusterRequest into its queue on
+// startup, which is what drives ReconcileClusterRequest for them.
+func run(m *jobManager) {
+	stopCh := make(chan struct{})
+
+	controller := clusterrequest.NewController(m.clusterRequestClient, m.prowNamespace, m)
+	go func() {
+		if err := controller.Run(stopCh); err != nil {
+			fmt.Fprintf(os.Stderr, "error: clusterrequest controller exited: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	sched := scheduler.New()
+	sched.Register(scheduler.NewTTLReaper(m.prowClient, m.coreClient.Core(), m.clusterRequestClient, m.prowNamespace))
+	sched.Register(scheduler.NewOrphanedNamespaceGC(m.prowClient, m.coreClient.Core(), m.prowNamespace))
+	sched.Register(scheduler.NewLaunchMetricsReporter(m.prowClient, m.prowNamespace))
+	sched.Start(stopCh)
+
+	select {}
+}
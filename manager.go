@@ -0,0 +1,114 @@
+package main
+
+import (
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	coreclientset "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+	"github.com/openshift/ci-chat-bot/pkg/prow/readiness"
+)
+
+// Job describes a single chat-requested cluster launch: what to build, who
+// asked for it, and where to tell them it's ready.
+type Job struct {
+	// Name identifies the launch. It is used as both the ProwJob name and the
+	// ClusterRequest name, so it must be a valid Kubernetes object name.
+	Name string
+	// Mode is one of the job manager's launch modes (e.g. "launch").
+	Mode string
+	// JobName identifies the prow periodic job config used to perform the launch.
+	JobName string
+
+	RequestedBy      string
+	RequestedChannel string
+
+	InstallImage   string
+	InstallVersion string
+	UpgradeImage   string
+	UpgradeVersion string
+
+	// URL is the prow job's status URL, populated once the job reports one.
+	URL string
+	// Credentials holds the launched cluster's kubeconfig once retrieved.
+	// It is cleared if the cluster fails to become reachable.
+	Credentials string
+	// PasswordSnippet is the short, non-sensitive excerpt of setup logs shown to the user.
+	PasswordSnippet string
+
+	// TTL overrides how long the launched cluster may exist before
+	// TTLReaper reaps it. Zero means use the scheduler's mode-based default.
+	TTL time.Duration
+	// LaunchTimeouts overrides the manager's default phase timeouts for this
+	// launch alone. Nil means use the manager's defaults for every phase.
+	LaunchTimeouts *LaunchTimeouts
+	// ReadyPolicy overrides the default cluster-readiness policy for this
+	// launch. Empty means use readiness.PolicyStandard.
+	ReadyPolicy readiness.Policy
+}
+
+// slackPoster posts a progress message to a chat channel. The concrete
+// Slack client lives in the bot's chat integration, outside the launch
+// pipeline, so launchJob only needs to depend on this narrow interface.
+type slackPoster interface {
+	PostMessage(channel, text string) error
+}
+
+// coreV1Getter exposes the core/v1 API group via Core(), the shape the
+// launch pipeline already calls it with throughout this package.
+type coreV1Getter interface {
+	Core() coreclientset.CoreV1Interface
+}
+
+// coreClientAdapter adapts a plain CoreV1Interface to coreV1Getter.
+type coreClientAdapter struct {
+	coreclientset.CoreV1Interface
+}
+
+func (a coreClientAdapter) Core() coreclientset.CoreV1Interface { return a.CoreV1Interface }
+
+// jobManager owns the clients and configuration launchJob needs to drive a
+// cluster from chat request to ready credentials, and is the receiver for
+// every step of that pipeline.
+type jobManager struct {
+	prowConfigLoader prow.ProwConfigLoader
+	prowNamespace    string
+
+	prowClient           dynamic.NamespaceableResourceInterface
+	clusterRequestClient dynamic.NamespaceableResourceInterface
+	coreClient           coreV1Getter
+	coreConfig           *rest.Config
+
+	// launchTimeouts holds the manager-wide defaults for each launch phase,
+	// layered under by timeoutsFor beneath any per-job override.
+	launchTimeouts LaunchTimeouts
+
+	// slackClient posts intermediate progress updates to the requester's
+	// chat channel. Nil disables progress notifications (e.g. in tests).
+	slackClient slackPoster
+}
+
+// NewJobManager constructs a jobManager wired to cfg, scoped to namespace for
+// both ProwJobs and ClusterRequests.
+func NewJobManager(cfg *rest.Config, prowConfigLoader prow.ProwConfigLoader, namespace string) (*jobManager, error) {
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	coreClient, err := coreclientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobManager{
+		prowConfigLoader:     prowConfigLoader,
+		prowNamespace:        namespace,
+		prowClient:           dynamicClient.Resource(prowJobResource),
+		clusterRequestClient: dynamicClient.Resource(clusterRequestResource),
+		coreClient:           coreClientAdapter{coreClient},
+		coreConfig:           cfg,
+		launchTimeouts:       DefaultLaunchTimeouts(),
+	}, nil
+}
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRequestPhase describes where a ClusterRequest is in its lifecycle.
+type ClusterRequestPhase string
+
+const (
+	ClusterRequestPhasePending   ClusterRequestPhase = "Pending"
+	ClusterRequestPhaseLaunching ClusterRequestPhase = "Launching"
+	ClusterRequestPhaseRunning   ClusterRequestPhase = "Running"
+	ClusterRequestPhaseFailed    ClusterRequestPhase = "Failed"
+	ClusterRequestPhaseExpired   ClusterRequestPhase = "Expired"
+)
+
+// ClusterRequestConditionType is a type of condition a ClusterRequest can report.
+type ClusterRequestConditionType string
+
+const (
+	// ClusterRequestJobLaunched indicates the backing ProwJob has been created.
+	ClusterRequestJobLaunched ClusterRequestConditionType = "JobLaunched"
+	// ClusterRequestClusterReachable indicates the cluster answers API requests.
+	ClusterRequestClusterReachable ClusterRequestConditionType = "ClusterReachable"
+	// ClusterRequestCredentialsReady indicates Status.CredentialsSecretRef is populated and usable.
+	ClusterRequestCredentialsReady ClusterRequestConditionType = "CredentialsReady"
+)
+
+// ClusterRequestCondition is a single observation of a ClusterRequest's state,
+// following the same shape conventions as the built-in Kubernetes conditions.
+type ClusterRequestCondition struct {
+	Type               ClusterRequestConditionType `json:"type"`
+	Status             corev1.ConditionStatus      `json:"status"`
+	LastTransitionTime metav1.Time                 `json:"lastTransitionTime,omitempty"`
+	Reason             string                      `json:"reason,omitempty"`
+	Message            string                      `json:"message,omitempty"`
+}
+
+// ClusterRequestSpec is the desired state of a launch requested through chat.
+type ClusterRequestSpec struct {
+	// Requester is the chat user (e.g. Slack user ID) who asked for the cluster.
+	Requester string `json:"requester"`
+	// Channel is the chat channel the bot should notify when the cluster is ready.
+	Channel string `json:"channel,omitempty"`
+	// Mode is one of the job manager's launch modes (e.g. "launch").
+	Mode string `json:"mode"`
+	// JobName identifies the prow periodic job config used to perform the launch.
+	JobName string `json:"jobName"`
+
+	// InstallImage is the release payload to install.
+	InstallImage string `json:"installImage"`
+	// InstallVersion is the release version being installed, if known.
+	InstallVersion string `json:"installVersion,omitempty"`
+	// UpgradeImage is the release payload to upgrade to, if this is an upgrade request.
+	UpgradeImage string `json:"upgradeImage,omitempty"`
+	// UpgradeVersion is the release version being upgraded to, if known.
+	UpgradeVersion string `json:"upgradeVersion,omitempty"`
+
+	// TTL bounds how long the cluster may exist before the scheduler reaps it.
+	TTL metav1.Duration `json:"ttl,omitempty"`
+
+	// LaunchTimeouts carries the per-request timeout/poll-interval overrides
+	// the requester asked for (job.LaunchTimeouts), so that a launch resumed
+	// by the controller after a crash uses the same budgets as the original
+	// request instead of reverting to the manager's defaults.
+	LaunchTimeouts *ClusterRequestLaunchTimeouts `json:"launchTimeouts,omitempty"`
+	// ReadyPolicy overrides the default cluster-readiness policy (job.ReadyPolicy).
+	ReadyPolicy string `json:"readyPolicy,omitempty"`
+}
+
+// ClusterRequestLaunchTimeouts mirrors the job manager's LaunchTimeouts so a
+// per-request override can round-trip through the ClusterRequest. The zero
+// value of any field means "use the manager's default for that phase",
+// matching LaunchTimeouts itself.
+type ClusterRequestLaunchTimeouts struct {
+	URLWait                      metav1.Duration `json:"urlWait,omitempty"`
+	URLPollInterval              metav1.Duration `json:"urlPollInterval,omitempty"`
+	ProwPodWait                  metav1.Duration `json:"prowPodWait,omitempty"`
+	ProwPodPollInterval          metav1.Duration `json:"prowPodPollInterval,omitempty"`
+	SetupWait                    metav1.Duration `json:"setupWait,omitempty"`
+	SetupPollInterval            metav1.Duration `json:"setupPollInterval,omitempty"`
+	KubeconfigWait               metav1.Duration `json:"kubeconfigWait,omitempty"`
+	KubeconfigPollInterval       metav1.Duration `json:"kubeconfigPollInterval,omitempty"`
+	ClusterReachableWait         metav1.Duration `json:"clusterReachableWait,omitempty"`
+	ClusterReachablePollInterval metav1.Duration `json:"clusterReachablePollInterval,omitempty"`
+}
+
+// ClusterRequestStatus is the observed state of a launch, and is the
+// authoritative record the job manager reconciles against after a restart.
+type ClusterRequestStatus struct {
+	Phase ClusterRequestPhase `json:"phase,omitempty"`
+
+	// ProwJobName is the name of the ProwJob created to satisfy this request.
+	ProwJobName string `json:"prowJobName,omitempty"`
+	// Namespace is the target namespace the cluster was launched into.
+	Namespace string `json:"namespace,omitempty"`
+	// URL is the prow job's status URL.
+	URL string `json:"url,omitempty"`
+
+	// CredentialsSecretRef names the Secret holding the cluster's kubeconfig.
+	// Credentials are never stored on the ClusterRequest itself.
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+	// PasswordSnippet is the short, non-sensitive excerpt of setup logs shown to the user.
+	PasswordSnippet string `json:"passwordSnippet,omitempty"`
+
+	Conditions []ClusterRequestCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterRequest records a single chat-requested cluster launch. It replaces
+// the previous scheme of tracking launch state as annotations on the ProwJob
+// and as in-memory fields on the job manager's Job struct, so that an
+// interrupted launch can be resumed by the controller instead of lost.
+type ClusterRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRequestSpec   `json:"spec"`
+	Status ClusterRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterRequestList is a list of ClusterRequest.
+type ClusterRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterRequest `json:"items"`
+}
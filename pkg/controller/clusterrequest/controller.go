@@ -0,0 +1,155 @@
+// Package clusterrequest implements an informer-driven controller that
+// reconciles chatbot.openshift.io/v1 ClusterRequest objects. The controller
+// exists so that a launch interrupted by a bot crash (for example, after the
+// target namespace was created but before the ProwJob was annotated) can be
+// resumed from the ClusterRequest's persisted Status rather than lost.
+package clusterrequest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2/klogr"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	chatbotv1 "github.com/openshift/ci-chat-bot/pkg/apis/chatbot/v1"
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+)
+
+// logger is the controller package's root logr.Logger, backed by klog/v2 so
+// reconcile logs share the same verbosity flags and output plumbing as the
+// rest of the launch pipeline.
+var logger logr.Logger = klogr.New().WithName("clusterrequest-controller")
+
+// resyncPeriod bounds how stale the informer's view of ClusterRequests can
+// get; launches are otherwise driven entirely by watch events.
+const resyncPeriod = 10 * time.Minute
+
+// Launcher resumes or performs the launch described by a ClusterRequest. The
+// job manager in package main implements this so that the controller does
+// not need to import it.
+type Launcher interface {
+	// ReconcileClusterRequest is called once at controller startup for every
+	// existing ClusterRequest, and again whenever one is added or updated.
+	// Implementations should be idempotent: calling it on a request that has
+	// already reached Status.Phase Running or Failed must be a no-op.
+	ReconcileClusterRequest(cr *chatbotv1.ClusterRequest) error
+}
+
+// Controller watches ClusterRequest objects and drives them to completion.
+type Controller struct {
+	client    dynamic.NamespaceableResourceInterface
+	namespace string
+	launcher  Launcher
+
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+}
+
+// NewController builds a Controller that watches ClusterRequests in namespace.
+func NewController(client dynamic.NamespaceableResourceInterface, namespace string, launcher Launcher) *Controller {
+	c := &Controller{
+		client:    client,
+		namespace: namespace,
+		launcher:  launcher,
+		queue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	c.informer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return c.client.Namespace(c.namespace).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return c.client.Namespace(c.namespace).Watch(options)
+			},
+		},
+		nil,
+		resyncPeriod,
+		cache.Indexers{},
+	)
+	c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		logger.Error(err, "could not compute key for object")
+		return
+	}
+	c.queue.Add(key)
+}
+
+// Run starts the informer and worker loop. It blocks until stopCh is closed.
+// On startup the informer's initial List populates every existing
+// ClusterRequest into the queue, which is what lets an interrupted launch
+// resume after the bot restarts.
+func (c *Controller) Run(stopCh <-chan struct{}) error {
+	defer c.queue.ShutDown()
+
+	go c.informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		return fmt.Errorf("timed out waiting for clusterrequest informer cache to sync")
+	}
+
+	go c.runWorker()
+
+	<-stopCh
+	return nil
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.reconcile(key.(string)); err != nil {
+		logger.Error(err, "failed to reconcile, retrying", "key", key)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) reconcile(key string) error {
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	item, exists, err := c.informer.GetStore().GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// the ClusterRequest was deleted; nothing to reconcile
+		return nil
+	}
+
+	var cr chatbotv1.ClusterRequest
+	if err := prow.UnstructuredToObject(item.(runtime.Unstructured), &cr); err != nil {
+		return fmt.Errorf("could not decode clusterrequest %s: %v", name, err)
+	}
+
+	return c.launcher.ReconcileClusterRequest(&cr)
+}
@@ -0,0 +1,313 @@
+// Package readiness waits for a launched cluster to become usable, not just
+// reachable. Reaching the API server only tells you that kube-apiserver is
+// answering requests; it says nothing about whether the cluster operators,
+// workloads, and nodes that make up the rest of the control plane have come
+// up. This package polls a configurable set of resources until they report
+// ready, or returns the first resource that did not.
+package readiness
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Policy selects which resource kinds must be ready before the cluster is
+// considered usable. Stricter policies take longer but give stronger
+// guarantees that a launched cluster is actually usable for testing.
+type Policy string
+
+const (
+	// PolicyMinimal waits only for all Nodes to be Ready.
+	PolicyMinimal Policy = "minimal"
+	// PolicyStandard additionally waits for all ClusterOperators to report
+	// Available=True, Progressing=False, Degraded=False.
+	PolicyStandard Policy = "standard"
+	// PolicyFull additionally waits for Deployments and DaemonSets in
+	// openshift-* namespaces to have all of their replicas available.
+	PolicyFull Policy = "full"
+)
+
+var clusterOperatorResource = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}
+
+// ProgressFunc is invoked after each poll iteration with how many of the
+// tracked resources are ready so callers (e.g. the chat bot) can post
+// intermediate status updates such as "7/12 cluster operators ready".
+type ProgressFunc func(ready, total int, message string)
+
+// Options configures a readiness wait.
+type Options struct {
+	// Policy selects which resource kinds are checked. Defaults to PolicyStandard.
+	Policy Policy
+	// PollInterval is how often to recheck. Defaults to 15s.
+	PollInterval time.Duration
+	// Timeout is the maximum time to wait before giving up. Defaults to 20m.
+	Timeout time.Duration
+	// Progress, if set, is called after every poll iteration.
+	Progress ProgressFunc
+}
+
+func (o Options) withDefaults() Options {
+	if o.Policy == "" {
+		o.Policy = PolicyStandard
+	}
+	if o.PollInterval == 0 {
+		o.PollInterval = 15 * time.Second
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 20 * time.Minute
+	}
+	return o
+}
+
+// NotReadyError is returned when a resource has not become ready within the
+// timeout. It carries enough detail (the resource's own reported conditions)
+// for a caller to show the operator why the wait failed.
+type NotReadyError struct {
+	Resource   string
+	Reason     string
+	Conditions []string
+}
+
+func (e *NotReadyError) Error() string {
+	msg := fmt.Sprintf("%s is not ready: %s", e.Resource, e.Reason)
+	if len(e.Conditions) > 0 {
+		msg = fmt.Sprintf("%s (%s)", msg, strings.Join(e.Conditions, ", "))
+	}
+	return msg
+}
+
+// WaitForClusterReady polls the cluster identified by cfg until every
+// resource required by opts.Policy reports ready or opts.Timeout elapses;
+// the wait cannot be cancelled early. On timeout it returns a *NotReadyError
+// describing the first resource that failed to become ready.
+func WaitForClusterReady(cfg *rest.Config, opts Options) error {
+	opts = opts.withDefaults()
+
+	client, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("could not create client: %v", err)
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("could not create dynamic client: %v", err)
+	}
+
+	var lastNotReady *NotReadyError
+	err = wait.PollImmediate(opts.PollInterval, opts.Timeout, func() (bool, error) {
+		checks := checksForPolicy(opts.Policy)
+
+		ready, total := 0, 0
+		var notReady *NotReadyError
+		for _, check := range checks {
+			r, t, err := check(client, dyn)
+			ready += r
+			total += t
+			if err != nil && notReady == nil {
+				notReady = err
+			}
+		}
+
+		if opts.Progress != nil {
+			message := "all tracked resources ready"
+			if notReady != nil {
+				message = notReady.Error()
+			}
+			opts.Progress(ready, total, message)
+		}
+
+		lastNotReady = notReady
+		return notReady == nil, nil
+	})
+	if err != nil {
+		if err == wait.ErrWaitTimeout && lastNotReady != nil {
+			return lastNotReady
+		}
+		return err
+	}
+	return nil
+}
+
+type checkFunc func(client clientset.Interface, dyn dynamic.Interface) (ready, total int, err *NotReadyError)
+
+func checksForPolicy(policy Policy) []checkFunc {
+	switch policy {
+	case PolicyFull:
+		return []checkFunc{checkNodes, checkClusterOperators, checkDeployments, checkDaemonSets}
+	case PolicyMinimal:
+		return []checkFunc{checkNodes}
+	case PolicyStandard:
+		fallthrough
+	default:
+		return []checkFunc{checkNodes, checkClusterOperators}
+	}
+}
+
+func checkNodes(client clientset.Interface, _ dynamic.Interface) (int, int, *NotReadyError) {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return 0, 0, &NotReadyError{Resource: "nodes", Reason: err.Error()}
+	}
+	ready := 0
+	for _, node := range nodes.Items {
+		if isNodeReady(&node) {
+			ready++
+			continue
+		}
+		return ready, len(nodes.Items), &NotReadyError{
+			Resource:   fmt.Sprintf("node/%s", node.Name),
+			Reason:     "node is not Ready",
+			Conditions: nodeConditionStrings(&node),
+		}
+	}
+	return ready, len(nodes.Items), nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func nodeConditionStrings(node *corev1.Node) []string {
+	conditions := make([]string, 0, len(node.Status.Conditions))
+	for _, cond := range node.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s", cond.Type, cond.Status))
+	}
+	return conditions
+}
+
+func checkClusterOperators(_ clientset.Interface, dyn dynamic.Interface) (int, int, *NotReadyError) {
+	list, err := dyn.Resource(clusterOperatorResource).List(metav1.ListOptions{})
+	if err != nil {
+		return 0, 0, &NotReadyError{Resource: "clusteroperators", Reason: err.Error()}
+	}
+	ready := 0
+	for _, item := range list.Items {
+		name := item.GetName()
+		available, progressing, degraded, conditions := clusterOperatorConditions(&item)
+		if available && !progressing && !degraded {
+			ready++
+			continue
+		}
+		return ready, len(list.Items), &NotReadyError{
+			Resource:   fmt.Sprintf("clusteroperator/%s", name),
+			Reason:     "operator is not Available=True, Progressing=False, Degraded=False",
+			Conditions: conditions,
+		}
+	}
+	return ready, len(list.Items), nil
+}
+
+func clusterOperatorConditions(obj *unstructured.Unstructured) (available, progressing, degraded bool, conditions []string) {
+	rawConditions, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return false, false, false, nil
+	}
+	for _, rc := range rawConditions {
+		cond, ok := rc.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		condStatus, _ := cond["status"].(string)
+		conditions = append(conditions, fmt.Sprintf("%s=%s", condType, condStatus))
+		switch condType {
+		case "Available":
+			available = condStatus == "True"
+		case "Progressing":
+			progressing = condStatus == "True"
+		case "Degraded":
+			degraded = condStatus == "True"
+		}
+	}
+	return available, progressing, degraded, conditions
+}
+
+func checkDeployments(client clientset.Interface, _ dynamic.Interface) (int, int, *NotReadyError) {
+	namespaces, err := openshiftNamespaces(client)
+	if err != nil {
+		return 0, 0, &NotReadyError{Resource: "namespaces", Reason: err.Error()}
+	}
+
+	ready, total := 0, 0
+	for _, ns := range namespaces {
+		deployments, err := client.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return ready, total, &NotReadyError{Resource: fmt.Sprintf("deployments/%s", ns), Reason: err.Error()}
+		}
+		for _, d := range deployments.Items {
+			total++
+			if deploymentAvailable(&d) {
+				ready++
+				continue
+			}
+			return ready, total, &NotReadyError{
+				Resource: fmt.Sprintf("deployment/%s/%s", d.Namespace, d.Name),
+				Reason:   fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, *d.Spec.Replicas),
+			}
+		}
+	}
+	return ready, total, nil
+}
+
+func deploymentAvailable(d *appsv1.Deployment) bool {
+	if d.Spec.Replicas == nil {
+		return true
+	}
+	return d.Status.AvailableReplicas >= *d.Spec.Replicas
+}
+
+func checkDaemonSets(client clientset.Interface, _ dynamic.Interface) (int, int, *NotReadyError) {
+	namespaces, err := openshiftNamespaces(client)
+	if err != nil {
+		return 0, 0, &NotReadyError{Resource: "namespaces", Reason: err.Error()}
+	}
+
+	ready, total := 0, 0
+	for _, ns := range namespaces {
+		daemonSets, err := client.AppsV1().DaemonSets(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return ready, total, &NotReadyError{Resource: fmt.Sprintf("daemonsets/%s", ns), Reason: err.Error()}
+		}
+		for _, ds := range daemonSets.Items {
+			total++
+			if ds.Status.NumberReady == ds.Status.DesiredNumberScheduled {
+				ready++
+				continue
+			}
+			return ready, total, &NotReadyError{
+				Resource: fmt.Sprintf("daemonset/%s/%s", ds.Namespace, ds.Name),
+				Reason:   fmt.Sprintf("%d/%d pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled),
+			}
+		}
+	}
+	return ready, total, nil
+}
+
+func openshiftNamespaces(client clientset.Interface) ([]string, error) {
+	list, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ns := range list.Items {
+		if strings.HasPrefix(ns.Name, "openshift-") {
+			names = append(names, ns.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,11 @@
+package scheduler
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2/klogr"
+)
+
+// logger is the scheduler package's root logr.Logger, backed by klog/v2 so
+// maintenance job logs share the same verbosity flags and output plumbing
+// (-v, -logtostderr, ...) as the rest of the launch pipeline.
+var logger logr.Logger = klogr.New().WithName("scheduler")
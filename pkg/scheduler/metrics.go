@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+	prowapiv1 "github.com/openshift/ci-chat-bot/pkg/prow/apiv1"
+)
+
+var (
+	launchesActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ci_chat_bot_launches_active",
+		Help: "Number of launches currently running.",
+	})
+	launchesQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ci_chat_bot_launches_queued",
+		Help: "Number of launches waiting to start.",
+	})
+	launchesFailed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ci_chat_bot_launches_failed",
+		Help: "Number of launches that have failed.",
+	})
+	launchesCompleted = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ci_chat_bot_launches_completed",
+		Help: "Number of launches that have completed successfully and are awaiting TTL cleanup.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(launchesActive, launchesQueued, launchesFailed, launchesCompleted)
+}
+
+// LaunchMetricsReporter periodically recomputes the active/queued/failed
+// launch gauges from the current set of ProwJobs so they can be scraped
+// without the rest of the bot needing to track counts itself.
+type LaunchMetricsReporter struct {
+	prowClient dynamic.NamespaceableResourceInterface
+	namespace  string
+}
+
+// NewLaunchMetricsReporter constructs a LaunchMetricsReporter for ProwJobs in namespace.
+func NewLaunchMetricsReporter(prowClient dynamic.NamespaceableResourceInterface, namespace string) *LaunchMetricsReporter {
+	return &LaunchMetricsReporter{prowClient: prowClient, namespace: namespace}
+}
+
+func (r *LaunchMetricsReporter) Name() string { return "launch-metrics-reporter" }
+
+func (r *LaunchMetricsReporter) Interval() time.Duration { return time.Minute }
+
+func (r *LaunchMetricsReporter) Run() error {
+	list, err := r.prowClient.Namespace(r.namespace).List(metav1.ListOptions{LabelSelector: launchLabelSelector})
+	if err != nil {
+		return fmt.Errorf("could not list launch prow jobs: %v", err)
+	}
+
+	var active, queued, failed, completed float64
+	for _, item := range list.Items {
+		var pj prowapiv1.ProwJob
+		if err := prow.UnstructuredToObject(&item, &pj); err != nil {
+			continue
+		}
+		switch pj.Status.State {
+		case prowapiv1.TriggeredState:
+			queued++
+		case prowapiv1.PendingState:
+			active++
+		case prowapiv1.SuccessState:
+			completed++
+		case prowapiv1.FailureState, prowapiv1.ErrorState, prowapiv1.AbortedState:
+			failed++
+		}
+	}
+
+	launchesActive.Set(active)
+	launchesQueued.Set(queued)
+	launchesFailed.Set(failed)
+	launchesCompleted.Set(completed)
+	return nil
+}
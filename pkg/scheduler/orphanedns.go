@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+	prowapiv1 "github.com/openshift/ci-chat-bot/pkg/prow/apiv1"
+)
+
+// ciLaunchNamespacePrefix is the prefix launchJob gives every target namespace.
+const ciLaunchNamespacePrefix = "ci-ln-"
+
+// OrphanedNamespaceGC deletes ci-ln-* namespaces that have no corresponding
+// ProwJob. This covers the case TTLReaper can't: a launch that crashed after
+// the target namespace was created but before the ProwJob was ever recorded,
+// which otherwise leaks the namespace forever since nothing ever reaps it.
+type OrphanedNamespaceGC struct {
+	prowClient dynamic.NamespaceableResourceInterface
+	coreClient corev1client.CoreV1Interface
+	namespace  string
+}
+
+// NewOrphanedNamespaceGC constructs an OrphanedNamespaceGC that looks for
+// ProwJobs in namespace.
+func NewOrphanedNamespaceGC(prowClient dynamic.NamespaceableResourceInterface, coreClient corev1client.CoreV1Interface, namespace string) *OrphanedNamespaceGC {
+	return &OrphanedNamespaceGC{prowClient: prowClient, coreClient: coreClient, namespace: namespace}
+}
+
+func (g *OrphanedNamespaceGC) Name() string { return "orphaned-namespace-gc" }
+
+func (g *OrphanedNamespaceGC) Interval() time.Duration { return 30 * time.Minute }
+
+func (g *OrphanedNamespaceGC) Run() error {
+	list, err := g.prowClient.Namespace(g.namespace).List(metav1.ListOptions{LabelSelector: launchLabelSelector})
+	if err != nil {
+		return fmt.Errorf("could not list launch prow jobs: %v", err)
+	}
+
+	live := make(map[string]bool, len(list.Items))
+	for _, item := range list.Items {
+		var pj prowapiv1.ProwJob
+		if err := prow.UnstructuredToObject(&item, &pj); err != nil {
+			logger.Error(err, "could not decode prow job", "job_name", item.GetName())
+			continue
+		}
+		if ns := pj.Annotations["ci-chat-bot.openshift.io/ns"]; len(ns) > 0 {
+			live[ns] = true
+		}
+	}
+
+	namespaces, err := g.coreClient.Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("could not list namespaces: %v", err)
+	}
+	for _, ns := range namespaces.Items {
+		if !strings.HasPrefix(ns.Name, ciLaunchNamespacePrefix) {
+			continue
+		}
+		if live[ns.Name] {
+			continue
+		}
+		logger.Info("namespace has no matching prow job, deleting", "namespace", ns.Name)
+		if err := g.coreClient.Namespaces().Delete(ns.Name, &metav1.DeleteOptions{}); err != nil && !isNotFound(err) {
+			logger.Error(err, "could not delete namespace", "namespace", ns.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package scheduler runs periodic maintenance jobs for the chat bot, such as
+// reaping expired launches and garbage-collecting namespaces left behind by
+// launches that crashed before cleanup. Each job declares its own interval
+// and can be individually enabled or disabled by name with Enable and
+// Disable, rather than the bot hard-coding a single maintenance loop. The
+// scheduler reads no configuration file or flags of its own.
+package scheduler
+
+import (
+	"time"
+)
+
+// Job is a single named unit of periodic maintenance work.
+type Job interface {
+	// Name identifies the job in logs and to Enable and Disable.
+	Name() string
+	// Interval is how often Run should be invoked.
+	Interval() time.Duration
+	// Run performs one pass of the job's work. Errors are logged but do not
+	// stop the scheduler from invoking Run again on the next interval.
+	Run() error
+}
+
+// Scheduler runs a set of registered Jobs on their own intervals until stopped.
+type Scheduler struct {
+	jobs    []Job
+	enabled map[string]bool
+}
+
+// New creates an empty Scheduler.
+func New() *Scheduler {
+	return &Scheduler{enabled: make(map[string]bool)}
+}
+
+// Register adds job to the scheduler. Jobs are enabled by default; call
+// Disable(job.Name()) to register a job without starting it.
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+	s.enabled[job.Name()] = true
+}
+
+// Enable turns on a previously registered job.
+func (s *Scheduler) Enable(name string) {
+	s.enabled[name] = true
+}
+
+// Disable turns off a previously registered job so that Start does not run
+// it. It has no effect on a job that Start has already started.
+func (s *Scheduler) Disable(name string) {
+	s.enabled[name] = false
+}
+
+// Start launches a goroutine per enabled job that calls Run on job.Interval()
+// until stopCh is closed.
+func (s *Scheduler) Start(stopCh <-chan struct{}) {
+	for _, job := range s.jobs {
+		if !s.enabled[job.Name()] {
+			logger.V(1).Info("job is disabled, not starting", "job", job.Name())
+			continue
+		}
+		go s.runJob(job, stopCh)
+	}
+}
+
+func (s *Scheduler) runJob(job Job, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(job.Interval())
+	defer ticker.Stop()
+
+	logger.V(1).Info("starting job", "job", job.Name(), "interval", job.Interval())
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := job.Run(); err != nil {
+				logger.Error(err, "job failed", "job", job.Name())
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	chatbotv1 "github.com/openshift/ci-chat-bot/pkg/apis/chatbot/v1"
+	"github.com/openshift/ci-chat-bot/pkg/prow"
+	prowapiv1 "github.com/openshift/ci-chat-bot/pkg/prow/apiv1"
+)
+
+// launchLabelSelector matches every ProwJob created by the chat bot to launch a cluster.
+const launchLabelSelector = "ci-chat-bot.openshift.io/launch=true"
+
+// DefaultTTL is how long a launch may exist before TTLReaper deletes it, for
+// modes without an explicit override.
+const DefaultTTL = 3 * time.Hour
+
+// TTLReaper deletes launches that have outlived their TTL: the ProwJob that
+// drove the launch and the target namespace it stood up. This bounds how
+// long a launch can leak resources if the requester never tears it down
+// and, combined with OrphanedNamespaceGC, keeps the cluster from filling up
+// with abandoned ci-ln-* namespaces.
+type TTLReaper struct {
+	prowClient           dynamic.NamespaceableResourceInterface
+	coreClient           corev1client.CoreV1Interface
+	clusterRequestClient dynamic.NamespaceableResourceInterface
+	namespace            string
+
+	// DefaultTTL is used for launches whose mode has no entry in PerModeTTL.
+	DefaultTTL time.Duration
+	// PerModeTTL overrides DefaultTTL for specific launch modes (e.g. "upgrade").
+	PerModeTTL map[string]time.Duration
+}
+
+// NewTTLReaper constructs a TTLReaper that reaps ProwJobs in namespace.
+func NewTTLReaper(prowClient dynamic.NamespaceableResourceInterface, coreClient corev1client.CoreV1Interface, clusterRequestClient dynamic.NamespaceableResourceInterface, namespace string) *TTLReaper {
+	return &TTLReaper{
+		prowClient:           prowClient,
+		coreClient:           coreClient,
+		clusterRequestClient: clusterRequestClient,
+		namespace:            namespace,
+		DefaultTTL:           DefaultTTL,
+		PerModeTTL:           make(map[string]time.Duration),
+	}
+}
+
+func (r *TTLReaper) Name() string { return "ttl-reaper" }
+
+func (r *TTLReaper) Interval() time.Duration { return 10 * time.Minute }
+
+func (r *TTLReaper) Run() error {
+	list, err := r.prowClient.Namespace(r.namespace).List(metav1.ListOptions{LabelSelector: launchLabelSelector})
+	if err != nil {
+		return fmt.Errorf("could not list launch prow jobs: %v", err)
+	}
+
+	for _, item := range list.Items {
+		var pj prowapiv1.ProwJob
+		if err := prow.UnstructuredToObject(&item, &pj); err != nil {
+			logger.Error(err, "could not decode prow job", "job_name", item.GetName())
+			continue
+		}
+
+		if pj.Status.StartTime.IsZero() {
+			continue
+		}
+
+		cr := r.getClusterRequest(pj.Name)
+		ttl := r.ttlFor(pj.Annotations["ci-chat-bot.openshift.io/mode"], cr)
+		age := time.Since(pj.Status.StartTime.Time)
+		if age < ttl {
+			continue
+		}
+
+		logger.Info("reaping expired launch", "job_name", pj.Name, "age", age.Round(time.Second).String(), "ttl", ttl.String())
+
+		targetNamespace := pj.Annotations["ci-chat-bot.openshift.io/ns"]
+		if len(targetNamespace) > 0 {
+			if err := r.coreClient.Namespaces().Delete(targetNamespace, &metav1.DeleteOptions{}); err != nil && !isNotFound(err) {
+				logger.Error(err, "could not delete namespace", "job_name", pj.Name, "namespace", targetNamespace)
+			}
+		}
+		if err := r.prowClient.Namespace(r.namespace).Delete(pj.Name, &metav1.DeleteOptions{}); err != nil && !isNotFound(err) {
+			logger.Error(err, "could not delete prow job", "job_name", pj.Name)
+		}
+		if cr != nil {
+			r.expireClusterRequest(cr)
+		}
+	}
+	return nil
+}
+
+// getClusterRequest returns the ClusterRequest backing the named launch, or
+// nil if it has no ClusterRequest (or the client wasn't configured) so that
+// callers can fall back to the mode-based TTL.
+func (r *TTLReaper) getClusterRequest(name string) *chatbotv1.ClusterRequest {
+	if r.clusterRequestClient == nil {
+		return nil
+	}
+	uns, err := r.clusterRequestClient.Namespace(r.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !isNotFound(err) {
+			logger.Error(err, "could not retrieve cluster request", "job_name", name)
+		}
+		return nil
+	}
+	var cr chatbotv1.ClusterRequest
+	if err := prow.UnstructuredToObject(uns, &cr); err != nil {
+		logger.Error(err, "could not decode cluster request", "job_name", name)
+		return nil
+	}
+	return &cr
+}
+
+// expireClusterRequest marks cr as Expired now that TTLReaper has deleted
+// the ProwJob and namespace backing it, so the ClusterRequest doesn't keep
+// reporting a stale phase (e.g. Running) for a cluster that no longer exists.
+func (r *TTLReaper) expireClusterRequest(cr *chatbotv1.ClusterRequest) {
+	cr.Status.Phase = chatbotv1.ClusterRequestPhaseExpired
+	if _, err := r.clusterRequestClient.Namespace(r.namespace).UpdateStatus(prow.ObjectToUnstructured(cr), metav1.UpdateOptions{}); err != nil {
+		logger.Error(err, "could not mark cluster request expired", "job_name", cr.Name)
+	}
+}
+
+// ttlFor resolves the TTL for a launch: a ClusterRequest's own Spec.TTL, if
+// set, takes precedence over the mode-based PerModeTTL/DefaultTTL so a
+// per-request override survives even if the mode's default changes later.
+func (r *TTLReaper) ttlFor(mode string, cr *chatbotv1.ClusterRequest) time.Duration {
+	if cr != nil && cr.Spec.TTL.Duration > 0 {
+		return cr.Spec.TTL.Duration
+	}
+	if ttl, ok := r.PerModeTTL[mode]; ok {
+		return ttl
+	}
+	if r.DefaultTTL > 0 {
+		return r.DefaultTTL
+	}
+	return DefaultTTL
+}
@@ -0,0 +1,7 @@
+package scheduler
+
+import "k8s.io/apimachinery/pkg/api/errors"
+
+func isNotFound(err error) bool {
+	return errors.IsNotFound(err)
+}
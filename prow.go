@@ -5,11 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/base32"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/spf13/pflag"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,10 +26,110 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/remotecommand"
 
+	chatbotv1 "github.com/openshift/ci-chat-bot/pkg/apis/chatbot/v1"
 	"github.com/openshift/ci-chat-bot/pkg/prow"
 	prowapiv1 "github.com/openshift/ci-chat-bot/pkg/prow/apiv1"
+	"github.com/openshift/ci-chat-bot/pkg/prow/readiness"
 )
 
+// LaunchTimeouts holds the wait budgets for each phase of launchJob. The
+// zero value of any field means "use the default for that phase": for a
+// per-job override that is the manager's configured value, and for the
+// manager's own values it is DefaultLaunchTimeouts.
+// Jobs may override any subset of these on a per-request basis (for
+// instance, long-running upgrade tests often need more than the default
+// SetupWait) by setting job.LaunchTimeouts.
+type LaunchTimeouts struct {
+	URLWait                      time.Duration
+	URLPollInterval              time.Duration
+	ProwPodWait                  time.Duration
+	ProwPodPollInterval          time.Duration
+	SetupWait                    time.Duration
+	SetupPollInterval            time.Duration
+	KubeconfigWait               time.Duration
+	KubeconfigPollInterval       time.Duration
+	ClusterReachableWait         time.Duration
+	ClusterReachablePollInterval time.Duration
+}
+
+// DefaultLaunchTimeouts returns the historical hard-coded wait budgets.
+func DefaultLaunchTimeouts() LaunchTimeouts {
+	return LaunchTimeouts{
+		URLWait:                      15 * time.Minute,
+		URLPollInterval:              10 * time.Second,
+		ProwPodWait:                  15 * time.Minute,
+		ProwPodPollInterval:          5 * time.Second,
+		SetupWait:                    45 * time.Minute,
+		SetupPollInterval:            5 * time.Second,
+		KubeconfigWait:               10 * time.Minute,
+		KubeconfigPollInterval:       30 * time.Second,
+		ClusterReachableWait:         20 * time.Minute,
+		ClusterReachablePollInterval: 15 * time.Second,
+	}
+}
+
+// AddFlags registers the launch timeouts as CLI flags, defaulting to t's
+// current values. Call this with the jobManager's LaunchTimeouts before
+// flag.Parse() so operators can tune phase budgets without a rebuild.
+func (t *LaunchTimeouts) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&t.URLWait, "launch-url-timeout", t.URLWait, "Maximum time to wait for a prow job to report a URL.")
+	fs.DurationVar(&t.URLPollInterval, "launch-url-poll-interval", t.URLPollInterval, "Polling interval while waiting for a prow job to report a URL.")
+	fs.DurationVar(&t.ProwPodWait, "launch-prow-pod-timeout", t.ProwPodWait, "Maximum time to wait for the prow job's pod to start.")
+	fs.DurationVar(&t.ProwPodPollInterval, "launch-prow-pod-poll-interval", t.ProwPodPollInterval, "Polling interval while waiting for the prow job's pod to start.")
+	fs.DurationVar(&t.SetupWait, "launch-setup-timeout", t.SetupWait, "Maximum time to wait for the setup container in the target pod to complete.")
+	fs.DurationVar(&t.SetupPollInterval, "launch-setup-poll-interval", t.SetupPollInterval, "Polling interval while waiting for the setup container in the target pod to complete.")
+	fs.DurationVar(&t.KubeconfigWait, "launch-kubeconfig-timeout", t.KubeconfigWait, "Maximum time to wait for the kubeconfig to be retrievable from the target pod.")
+	fs.DurationVar(&t.KubeconfigPollInterval, "launch-kubeconfig-poll-interval", t.KubeconfigPollInterval, "Polling interval while waiting for the kubeconfig to be retrievable from the target pod.")
+	fs.DurationVar(&t.ClusterReachableWait, "launch-cluster-reachable-timeout", t.ClusterReachableWait, "Maximum time to wait for the launched cluster to become reachable.")
+	fs.DurationVar(&t.ClusterReachablePollInterval, "launch-cluster-reachable-poll-interval", t.ClusterReachablePollInterval, "Polling interval while waiting for the launched cluster to become reachable.")
+}
+
+// withDefaults fills any zero-valued field of t with the corresponding
+// value from defaults, returning the merged result.
+func (t LaunchTimeouts) withDefaults(defaults LaunchTimeouts) LaunchTimeouts {
+	if t.URLWait == 0 {
+		t.URLWait = defaults.URLWait
+	}
+	if t.URLPollInterval == 0 {
+		t.URLPollInterval = defaults.URLPollInterval
+	}
+	if t.ProwPodWait == 0 {
+		t.ProwPodWait = defaults.ProwPodWait
+	}
+	if t.ProwPodPollInterval == 0 {
+		t.ProwPodPollInterval = defaults.ProwPodPollInterval
+	}
+	if t.SetupWait == 0 {
+		t.SetupWait = defaults.SetupWait
+	}
+	if t.SetupPollInterval == 0 {
+		t.SetupPollInterval = defaults.SetupPollInterval
+	}
+	if t.KubeconfigWait == 0 {
+		t.KubeconfigWait = defaults.KubeconfigWait
+	}
+	if t.KubeconfigPollInterval == 0 {
+		t.KubeconfigPollInterval = defaults.KubeconfigPollInterval
+	}
+	if t.ClusterReachableWait == 0 {
+		t.ClusterReachableWait = defaults.ClusterReachableWait
+	}
+	if t.ClusterReachablePollInterval == 0 {
+		t.ClusterReachablePollInterval = defaults.ClusterReachablePollInterval
+	}
+	return t
+}
+
+// timeoutsFor resolves the effective timeouts for job, layering the
+// manager's configured defaults under any per-job override.
+func (m *jobManager) timeoutsFor(job *Job) LaunchTimeouts {
+	base := m.launchTimeouts.withDefaults(DefaultLaunchTimeouts())
+	if job.LaunchTimeouts == nil {
+		return base
+	}
+	return job.LaunchTimeouts.withDefaults(base)
+}
+
 func findTargetName(spec *corev1.PodSpec) (string, error) {
 	if spec == nil {
 		return "", fmt.Errorf("prow job has no pod spec, cannot find target pod name")
@@ -46,6 +150,24 @@ func findTargetName(spec *corev1.PodSpec) (string, error) {
 	return "", fmt.Errorf("could not find argument --target=X in prow job pod spec to identify target pod name")
 }
 
+// inFlightLaunches tracks the ClusterRequest names this process currently
+// has a launchJob call actively driving. ensureClusterRequest's Create
+// fires an Add event on the clusterrequest informer, which would otherwise
+// start a second, concurrent launchJob for the same request out of
+// ReconcileClusterRequest while the direct chat-command call is still
+// running; claiming the name here lets the redundant call no-op instead of
+// racing the original to update the ClusterRequest's status.
+var inFlightLaunches sync.Map
+
+// claimLaunch reports whether name was not already claimed as in-flight and,
+// if so, claims it. Call the returned release func once launchJob returns.
+func claimLaunch(name string) (release func(), ok bool) {
+	if _, loaded := inFlightLaunches.LoadOrStore(name, struct{}{}); loaded {
+		return func() {}, false
+	}
+	return func() { inFlightLaunches.Delete(name) }, true
+}
+
 // launchJob creates a ProwJob and watches its status as it goes.
 // This is a long running function but should also be reentrant.
 func (m *jobManager) launchJob(job *Job) error {
@@ -53,14 +175,29 @@ func (m *jobManager) launchJob(job *Job) error {
 		return nil
 	}
 
+	release, ok := claimLaunch(job.Name)
+	if !ok {
+		// another goroutine in this process (the direct chat-command path or
+		// a previous reconcile) is already driving this request to
+		// completion; let it finish rather than racing it
+		return nil
+	}
+	defer release()
+
 	namespace := fmt.Sprintf("ci-ln-%s", namespaceSafeHash(job.Name))
-	// launch a prow job, tied back to this cluster user
-	pj, err := prow.JobForConfig(m.prowConfigLoader, job.JobName)
+	requestID := uuid.New().String()
+	logger := jobLogger(job, namespace, requestID)
+
+	// the ClusterRequest is the source of truth for this launch; creating it
+	// before anything else means a crash at any later point can be resumed
+	// by the clusterrequest controller from Status instead of losing the job
+	cr, err := m.ensureClusterRequest(job)
 	if err != nil {
 		return err
 	}
 
-	targetPodName, err := findTargetName(pj.Spec.PodSpec)
+	// launch a prow job, tied back to this cluster user
+	pj, err := prow.JobForConfig(m.prowConfigLoader, job.JobName)
 	if err != nil {
 		return err
 	}
@@ -75,6 +212,7 @@ func (m *jobManager) launchJob(job *Job) error {
 			"ci-chat-bot.openshift.io/ns":           namespace,
 			"ci-chat-bot.openshift.io/releaseImage": job.InstallImage,
 			"ci-chat-bot.openshift.io/upgradeImage": job.UpgradeImage,
+			"ci-chat-bot.openshift.io/requestID":    requestID,
 
 			"prow.k8s.io/job": pj.Spec.Job,
 		},
@@ -108,8 +246,18 @@ func (m *jobManager) launchJob(job *Job) error {
 		}
 	}
 
-	log.Printf("prow job %s launched to target namespace %s", job.Name, namespace)
-	err = wait.PollImmediate(10*time.Second, 15*time.Minute, func() (bool, error) {
+	cr.Status.Phase = chatbotv1.ClusterRequestPhaseLaunching
+	cr.Status.ProwJobName = job.Name
+	cr.Status.Namespace = namespace
+	setClusterRequestCondition(cr, chatbotv1.ClusterRequestJobLaunched, corev1.ConditionTrue, "Created", "prow job created")
+	if err := m.updateClusterRequestStatus(cr); err != nil {
+		logger.Error(err, "unable to update cluster request status")
+	}
+
+	timeouts := m.timeoutsFor(job)
+
+	logger.V(1).Info("prow job launched")
+	err = wait.PollImmediate(timeouts.URLPollInterval, timeouts.URLWait, func() (bool, error) {
 		uns, err := m.prowClient.Namespace(m.prowNamespace).Get(job.Name, metav1.GetOptions{})
 		if err != nil {
 			return false, err
@@ -118,6 +266,7 @@ func (m *jobManager) launchJob(job *Job) error {
 		if err := prow.UnstructuredToObject(uns, &pj); err != nil {
 			return false, err
 		}
+		logger.V(4).Info("polled for prow job status URL", "url", pj.Status.URL)
 		if len(pj.Status.URL) > 0 {
 			job.URL = pj.Status.URL
 			return true, nil
@@ -125,15 +274,24 @@ func (m *jobManager) launchJob(job *Job) error {
 		return false, nil
 	})
 	if err != nil {
-		return fmt.Errorf("did not retrieve job url due to an error: %v", err)
+		cr.Status.Phase = chatbotv1.ClusterRequestPhaseFailed
+		_ = m.updateClusterRequestStatus(cr)
+		return fmt.Errorf("prow job never reported a status URL within %s: %v", timeouts.URLWait, err)
+	}
+
+	cr.Status.URL = job.URL
+	if err := m.updateClusterRequestStatus(cr); err != nil {
+		logger.Error(err, "unable to update cluster request status")
 	}
 
 	if job.Mode != "launch" {
+		cr.Status.Phase = chatbotv1.ClusterRequestPhaseRunning
+		_ = m.updateClusterRequestStatus(cr)
 		return nil
 	}
 
 	seen := false
-	err = wait.PollImmediate(5*time.Second, 15*time.Minute, func() (bool, error) {
+	err = wait.PollImmediate(timeouts.ProwPodPollInterval, timeouts.ProwPodWait, func() (bool, error) {
 		pod, err := m.coreClient.Core().Pods(m.prowNamespace).Get(job.Name, metav1.GetOptions{})
 		if err != nil {
 			if !errors.IsNotFound(err) {
@@ -151,14 +309,19 @@ func (m *jobManager) launchJob(job *Job) error {
 		return true, nil
 	})
 	if err != nil {
-		return fmt.Errorf("unable to check launch status: %v", err)
+		return fmt.Errorf("prow job's pod did not start within %s: %v", timeouts.ProwPodWait, err)
 	}
 
-	log.Printf("waiting for setup container in pod %s/%s to complete", namespace, targetPodName)
+	targetPodName, err := m.discoverTargetPod(logger, namespace, job.Name, pj.Spec.PodSpec)
+	if err != nil {
+		return fmt.Errorf("could not determine which pod to watch in namespace %s: %v", namespace, err)
+	}
+
+	logger.V(1).Info("waiting for setup container to complete", "target_pod", targetPodName)
 
 	seen = false
 	var lastErr error
-	err = wait.PollImmediate(5*time.Second, 45*time.Minute, func() (bool, error) {
+	err = wait.PollImmediate(timeouts.SetupPollInterval, timeouts.SetupWait, func() (bool, error) {
 		pod, err := m.coreClient.Core().Pods(namespace).Get(targetPodName, metav1.GetOptions{})
 		if err != nil {
 			// pod could not be created or we may not have permission yet
@@ -179,20 +342,21 @@ func (m *jobManager) launchJob(job *Job) error {
 		if err != nil {
 			return false, err
 		}
+		logger.V(4).Info("polled setup container status", "target_pod", targetPodName, "phase", pod.Status.Phase, "setup_complete", ok)
 		return ok, nil
 	})
 	if err != nil {
 		if lastErr != nil && err == wait.ErrWaitTimeout {
 			err = lastErr
 		}
-		return fmt.Errorf("pod never became available: %v", err)
+		return fmt.Errorf("setup container in pod %s/%s never completed within %s: %v", namespace, targetPodName, timeouts.SetupWait, err)
 	}
 
-	log.Printf("trying to grab the kubeconfig from launched pod")
+	logger.V(1).Info("trying to grab the kubeconfig from launched pod", "target_pod", targetPodName)
 
 	var kubeconfig string
-	err = wait.PollImmediate(30*time.Second, 10*time.Minute, func() (bool, error) {
-		contents, err := commandContents(m.coreClient.Core(), m.coreConfig, namespace, targetPodName, "test", []string{"cat", "/tmp/admin.kubeconfig"})
+	err = wait.PollImmediate(timeouts.KubeconfigPollInterval, timeouts.KubeconfigWait, func() (bool, error) {
+		contents, err := commandContents(logger, m.coreClient.Core(), m.coreConfig, namespace, targetPodName, "test", []string{"cat", "/tmp/admin.kubeconfig"})
 		if err != nil {
 			if strings.Contains(err.Error(), "container not found") {
 				// periodically check whether the still exists and is not succeeded or failed
@@ -203,38 +367,68 @@ func (m *jobManager) launchJob(job *Job) error {
 
 				return false, nil
 			}
-			log.Printf("Unable to retrieve config contents: %v", err)
+			logger.V(4).Info("unable to retrieve kubeconfig contents, will retry", "error", err.Error())
 			return false, nil
 		}
 		kubeconfig = contents
 		return len(contents) > 0, nil
 	})
 	if err != nil {
-		return fmt.Errorf("could not retrieve kubeconfig from pod: %v", err)
+		return fmt.Errorf("could not retrieve kubeconfig from pod within %s: %v", timeouts.KubeconfigWait, err)
 	}
 
 	job.Credentials = kubeconfig
 
-	// once the cluster is reachable, we're ok to send credentials
-	// TODO: better criteria?
+	secretRef, err := m.persistCredentials(namespace, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("could not persist cluster credentials: %v", err)
+	}
+	cr.Status.CredentialsSecretRef = secretRef
+	setClusterRequestCondition(cr, chatbotv1.ClusterRequestCredentialsReady, corev1.ConditionTrue, "Persisted", "kubeconfig stored in secret")
+	if err := m.updateClusterRequestStatus(cr); err != nil {
+		logger.Error(err, "unable to update cluster request status")
+	}
+
+	// once the cluster is reachable and ready, we're ok to send credentials
+	policy := job.ReadyPolicy
+	if policy == "" {
+		policy = readiness.PolicyStandard
+	}
+	progress := func(ready, total int, message string) {
+		logger.V(1).Info("cluster readiness", "ready", ready, "total", total, "message", message)
+		if m.slackClient != nil && len(job.RequestedChannel) > 0 {
+			if err := m.slackClient.PostMessage(job.RequestedChannel, fmt.Sprintf("cluster readiness: %d/%d - %s", ready, total, message)); err != nil {
+				logger.Error(err, "unable to post cluster readiness update to slack")
+			}
+		}
+	}
 	var waitErr error
-	if err := waitForClusterReachable(kubeconfig); err != nil {
-		log.Printf("error: unable to wait for the cluster to start: %v", err)
+	if err := waitForClusterReachable(logger, kubeconfig, policy, timeouts.ClusterReachableWait, timeouts.ClusterReachablePollInterval, progress); err != nil {
+		logger.Error(err, "unable to wait for the cluster to start")
 		job.Credentials = ""
-		waitErr = fmt.Errorf("cluster did not become reachable: %v", err)
+		waitErr = fmt.Errorf("cluster did not become reachable within %s: %v", timeouts.ClusterReachableWait, err)
+		cr.Status.Phase = chatbotv1.ClusterRequestPhaseFailed
+		setClusterRequestCondition(cr, chatbotv1.ClusterRequestClusterReachable, corev1.ConditionFalse, "Timeout", waitErr.Error())
+	} else {
+		cr.Status.Phase = chatbotv1.ClusterRequestPhaseRunning
+		setClusterRequestCondition(cr, chatbotv1.ClusterRequestClusterReachable, corev1.ConditionTrue, "Reachable", "cluster is responding and ready")
 	}
 
 	lines := int64(2)
 	logs, err := m.coreClient.Core().Pods(namespace).GetLogs(targetPodName, &corev1.PodLogOptions{Container: "setup", TailLines: &lines}).DoRaw()
 	if err != nil {
-		log.Printf("error: unable to get setup logs")
+		logger.Error(err, "unable to get setup logs")
 	}
 	job.PasswordSnippet = reFixLines.ReplaceAllString(string(logs), "$1")
+	cr.Status.PasswordSnippet = job.PasswordSnippet
+	if err := m.updateClusterRequestStatus(cr); err != nil {
+		logger.Error(err, "unable to update cluster request status")
+	}
 
 	// clear the channel notification in case we crash so we don't attempt to redeliver
 	patch := []byte(`{"metadata":{"annotations":{"ci-chat-bot.openshift.io/channel":""}}}`)
 	if _, err := m.prowClient.Namespace(m.prowNamespace).Patch(job.Name, types.MergePatchType, patch, metav1.UpdateOptions{}); err != nil {
-		log.Printf("error: unable to clear channel annotation from prow job: %v", err)
+		logger.Error(err, "unable to clear channel annotation from prow job")
 	}
 
 	return waitErr
@@ -242,9 +436,11 @@ func (m *jobManager) launchJob(job *Job) error {
 
 var reFixLines = regexp.MustCompile(`(?m)^level=info msg=\"(.*)\"$`)
 
-// waitForClusterReachable performs a slow poll, waiting for the cluster to come alive.
-// It returns an error if the cluster doesn't respond within the time limit.
-func waitForClusterReachable(kubeconfig string) error {
+// waitForClusterReachable waits for the API server to answer requests and
+// then, per policy, for the cluster's operators, workloads, and nodes to
+// settle into a usable state. It returns an error if the cluster doesn't
+// become ready within the time limit.
+func waitForClusterReachable(logger logr.Logger, kubeconfig string, policy readiness.Policy, timeout, pollInterval time.Duration, progress readiness.ProgressFunc) error {
 	cfg, err := loadKubeconfigContents(kubeconfig)
 	if err != nil {
 		return err
@@ -255,18 +451,41 @@ func waitForClusterReachable(kubeconfig string) error {
 		return err
 	}
 
-	return wait.PollImmediate(15*time.Second, 20*time.Minute, func() (bool, error) {
+	start := time.Now()
+	err = wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
 		_, err := client.Core().Namespaces().Get("openshift-apiserver", metav1.GetOptions{})
 		if err == nil {
 			return true, nil
 		}
-		log.Printf("cluster is not yet reachable %s: %v", cfg.Host, err)
+		logger.V(4).Info("cluster is not yet reachable", "host", cfg.Host, "error", err.Error())
 		return false, nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// The apiserver-reachability poll above already spent part of the
+	// overall timeout budget; only the remainder is left for the cluster
+	// to finish settling, so --launch-cluster-reachable-timeout remains an
+	// accurate upper bound on the whole wait.
+	remaining := timeout - time.Since(start)
+	if remaining <= 0 {
+		// a zero Timeout would fall back to readiness's own default, so leave
+		// just enough for a single readiness check instead
+		remaining = time.Nanosecond
+	}
+
+	return readiness.WaitForClusterReady(cfg, readiness.Options{
+		Policy:       policy,
+		Timeout:      remaining,
+		PollInterval: pollInterval,
+		Progress:     progress,
+	})
 }
 
 // commandContents fetches the result of invoking a command in the provided container from stdout.
-func commandContents(podClient coreclientset.CoreV1Interface, podRESTConfig *rest.Config, ns, name, containerName string, command []string) (string, error) {
+func commandContents(logger logr.Logger, podClient coreclientset.CoreV1Interface, podRESTConfig *rest.Config, ns, name, containerName string, command []string) (string, error) {
+	logger.V(4).Info("executing command in pod", "pod", name, "container", containerName, "command", command)
 	u := podClient.RESTClient().Post().Resource("pods").Namespace(ns).Name(name).SubResource("exec").VersionedParams(&corev1.PodExecOptions{
 		Container: containerName,
 		Stdout:    true,
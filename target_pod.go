@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ci-operator labels every pod it creates for a multi-stage test with these,
+// which lets us find the right target pod without parsing --target= out of
+// the launcher pod's own spec. That heuristic breaks the moment ci-operator
+// changes its CLI, and can't tell multiple target pods (e.g. an upgrade plus
+// a conformance run) apart.
+const (
+	createdByCILabel = "created-by-ci"
+	jobNameLabel     = "job-name"
+)
+
+// targetPodDiscoveryTimeout bounds how long we wait for a labelled target
+// pod to appear before falling back to the --target= heuristic.
+const targetPodDiscoveryTimeout = 2 * time.Minute
+
+// targetPodSelector matches every pod ci-operator creates in the target
+// namespace for jobName.
+func targetPodSelector(jobName string) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		createdByCILabel: "true",
+		jobNameLabel:     jobName,
+	})
+}
+
+// podsByReadiness sorts pods best-candidate-first: Ready pods before Running
+// pods before Pending pods before everything else, and within a tier the
+// newest pod first. This lets discoverTargetPod pick the right pod even
+// when ci-operator has created more than one target pod for a job (for
+// example an upgrade step followed by a conformance step).
+type podsByReadiness []corev1.Pod
+
+func (p podsByReadiness) Len() int      { return len(p) }
+func (p podsByReadiness) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p podsByReadiness) Less(i, j int) bool {
+	ri, rj := podReadinessRank(&p[i]), podReadinessRank(&p[j])
+	if ri != rj {
+		return ri > rj
+	}
+	return p[j].CreationTimestamp.Before(&p[i].CreationTimestamp)
+}
+
+// podReadinessRank scores a pod so that more useful pods sort first:
+// Ready > Running > Pending > anything else (e.g. Succeeded, Failed).
+func podReadinessRank(pod *corev1.Pod) int {
+	switch {
+	case isPodReady(pod):
+		return 2
+	case pod.Status.Phase == corev1.PodRunning:
+		return 1
+	case pod.Status.Phase == corev1.PodPending:
+		return 0
+	default:
+		return -1
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// discoverTargetPod finds the pod ci-operator created for jobName in
+// namespace, preferring the most ready, most recently created candidate.
+// When ci-operator has not yet labelled any pod (an older ci-operator, or a
+// job that hasn't created its target pod yet) it falls back to parsing
+// --target= out of the launcher pod's own spec, the historical behavior.
+func (m *jobManager) discoverTargetPod(logger logr.Logger, namespace, jobName string, launcherPodSpec *corev1.PodSpec) (string, error) {
+	selector := targetPodSelector(jobName)
+
+	deadline := time.Now().Add(targetPodDiscoveryTimeout)
+	for {
+		pods, err := m.coreClient.Core().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return "", fmt.Errorf("could not list target pods: %v", err)
+		}
+		if len(pods.Items) > 0 {
+			sorted := podsByReadiness(pods.Items)
+			sort.Sort(sorted)
+			logger.V(1).Info("discovered target pod via label selector", "target_pod", sorted[0].Name, "candidates", len(sorted))
+			return sorted[0].Name, nil
+		}
+
+		if time.Now().After(deadline) {
+			logger.V(1).Info("no labelled target pod appeared in time, falling back to --target= parsing")
+			return findTargetName(launcherPodSpec)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}